@@ -0,0 +1,50 @@
+// +build linux
+
+package network
+
+import "fmt"
+
+// configureInterface applies addressing, MTU, gateways and routes to an
+// interface that has already been renamed/placed inside the container's
+// namespace. It is shared by every strategy whose Initialize ends up
+// configuring a plain network device the same way once the device
+// itself exists (veth, macvlan, ipvlan).
+func configureInterface(device string, config *Network) error {
+	if config.MacAddress != "" {
+		if err := SetInterfaceMac(device, config.MacAddress); err != nil {
+			return fmt.Errorf("set %s mac %s", device, err)
+		}
+	}
+	if err := SetInterfaceIp(device, config.Address); err != nil {
+		return fmt.Errorf("set %s ip %s", device, err)
+	}
+	if config.IPv6Address != "" {
+		if err := SetInterfaceIp(device, config.IPv6Address); err != nil {
+			return fmt.Errorf("set %s ipv6 %s", device, err)
+		}
+	}
+
+	if err := SetMtu(device, config.Mtu); err != nil {
+		return fmt.Errorf("set %s mtu to %d %s", device, config.Mtu, err)
+	}
+	if err := InterfaceUp(device); err != nil {
+		return fmt.Errorf("%s up %s", device, err)
+	}
+	if config.Gateway != "" {
+		if err := SetDefaultGateway(config.Gateway, device); err != nil {
+			return fmt.Errorf("set gateway to %s on device %s failed with %s", config.Gateway, device, err)
+		}
+	}
+	if config.IPv6Gateway != "" {
+		if err := SetDefaultGateway(config.IPv6Gateway, device); err != nil {
+			return fmt.Errorf("set gateway for ipv6 to %s on device %s failed with %s", config.IPv6Gateway, device, err)
+		}
+	}
+	for _, route := range config.Routes {
+		if err := AddRoute(route.Dst, route.GW, route.Src, route.Metric, device); err != nil {
+			return fmt.Errorf("add route %s via %s on device %s failed with %s", route.Dst, route.GW, device, err)
+		}
+	}
+
+	return nil
+}
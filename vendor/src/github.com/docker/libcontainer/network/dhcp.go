@@ -0,0 +1,308 @@
+// +build linux
+
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// Lease is the result of a DHCPv4 DISCOVER/OFFER/REQUEST/ACK exchange. It
+// is persisted on the NetworkState itself, rather than as a pidfile under
+// /var/run and a lease file under /var/lib on the host, so it cleans up
+// with the container instead of leaking behind it.
+type Lease struct {
+	IP        string        `json:"ip"`
+	Mask      string        `json:"mask"`
+	Gateway   string        `json:"gateway"`
+	DNS       []string      `json:"dns"`
+	LeaseTime time.Duration `json:"lease_time"`
+}
+
+const (
+	dhcpDiscover   = 1
+	dhcpOffer      = 2
+	dhcpRequestMsg = 3
+	dhcpAck        = 5
+
+	optSubnetMask  = 1
+	optRouter      = 3
+	optDNS         = 6
+	optRequestedIP = 50
+	optLeaseTime   = 51
+	optMsgType     = 53
+	optServerID    = 54
+	optEnd         = 255
+
+	ethPIP = 0x0800
+)
+
+// dhcpPacket is the parsed subset of a BOOTP/DHCP reply this client
+// needs.
+type dhcpPacket struct {
+	xid     uint32
+	yiaddr  net.IP
+	options map[byte][]byte
+}
+
+// dhcpLease enters the network namespace of nspid, runs a DISCOVER /
+// OFFER / REQUEST / ACK exchange over a raw AF_PACKET socket bound to
+// device, and returns the lease it was granted. It does not touch the
+// kernel IP stack of the namespace it's operating in since device has no
+// address configured yet; the whole exchange is framed by hand.
+func dhcpLease(nspid int, device string) (*Lease, error) {
+	nsFile, err := os.Open(fmt.Sprintf("/proc/%d/ns/net", nspid))
+	if err != nil {
+		return nil, fmt.Errorf("open netns of pid %d %s", nspid, err)
+	}
+	defer nsFile.Close()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origNs, err := os.Open("/proc/self/ns/net")
+	if err != nil {
+		return nil, fmt.Errorf("open /proc/self/ns/net %s", err)
+	}
+	defer origNs.Close()
+
+	if err := setNs(nsFile.Fd()); err != nil {
+		return nil, fmt.Errorf("enter netns of pid %d %s", nspid, err)
+	}
+	defer setNs(origNs.Fd())
+
+	iface, err := net.InterfaceByName(device)
+	if err != nil {
+		return nil, fmt.Errorf("lookup %s %s", device, err)
+	}
+
+	sock, err := dhcpSocket(iface)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(sock)
+
+	xid := uint32(os.Getpid())
+
+	if err := sendDhcpPacket(sock, iface, dhcpDiscover, xid, nil); err != nil {
+		return nil, fmt.Errorf("send discover %s", err)
+	}
+	offer, err := recvDhcpPacket(sock, xid, dhcpOffer)
+	if err != nil {
+		return nil, fmt.Errorf("receive offer %s", err)
+	}
+
+	req := map[byte][]byte{
+		optRequestedIP: offer.yiaddr.To4(),
+		optServerID:    offer.options[optServerID],
+	}
+	if err := sendDhcpPacket(sock, iface, dhcpRequestMsg, xid, req); err != nil {
+		return nil, fmt.Errorf("send request %s", err)
+	}
+	ack, err := recvDhcpPacket(sock, xid, dhcpAck)
+	if err != nil {
+		return nil, fmt.Errorf("receive ack %s", err)
+	}
+
+	return leaseFromAck(ack), nil
+}
+
+// dhcpSocket opens a raw AF_PACKET socket bound to iface so whole DHCP
+// frames, Ethernet header included, can be sent and received before the
+// interface has an IP address. SOCK_RAW is required here rather than
+// SOCK_DGRAM because sendDhcpPacket/parseDhcpPayload build and expect
+// that Ethernet header themselves; SOCK_DGRAM has the kernel add and
+// strip it instead, which would double-frame outgoing packets and
+// misalign every field of incoming ones.
+func dhcpSocket(iface *net.Interface) (int, error) {
+	sock, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW, int(htons(ethPIP)))
+	if err != nil {
+		return -1, fmt.Errorf("open packet socket %s", err)
+	}
+	addr := syscall.SockaddrLinklayer{
+		Protocol: htons(ethPIP),
+		Ifindex:  iface.Index,
+	}
+	if err := syscall.Bind(sock, &addr); err != nil {
+		syscall.Close(sock)
+		return -1, fmt.Errorf("bind packet socket to %s %s", iface.Name, err)
+	}
+	timeout := syscall.Timeval{Sec: 10}
+	if err := syscall.SetsockoptTimeval(sock, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &timeout); err != nil {
+		syscall.Close(sock)
+		return -1, fmt.Errorf("set recv timeout on packet socket %s", err)
+	}
+	return sock, nil
+}
+
+func sendDhcpPacket(sock int, iface *net.Interface, msgType byte, xid uint32, extra map[byte][]byte) error {
+	payload := buildDhcpPayload(msgType, xid, iface.HardwareAddr, extra)
+	addr := syscall.SockaddrLinklayer{
+		Protocol: htons(ethPIP),
+		Ifindex:  iface.Index,
+		Halen:    6,
+	}
+	copy(addr.Addr[:6], broadcastMAC)
+	return syscall.Sendto(sock, payload, 0, &addr)
+}
+
+// recvDhcpPacket waits for a reply matching xid whose message type is
+// wantType, discarding anything else (including a DHCPNAK, which shares
+// the same xid as the OFFER/ACK it refused). dhcpSocket sets SO_RCVTIMEO
+// on sock, so a Recvfrom that never sees a matching reply still returns
+// within that timeout rather than blocking forever.
+func recvDhcpPacket(sock int, xid uint32, wantType byte) (*dhcpPacket, error) {
+	buf := make([]byte, 1500)
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		n, _, err := syscall.Recvfrom(sock, buf, 0)
+		if err == syscall.EAGAIN || err == syscall.EWOULDBLOCK {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		pkt, err := parseDhcpPayload(buf[:n])
+		if err != nil || pkt.xid != xid {
+			continue
+		}
+		if len(pkt.options[optMsgType]) != 1 || pkt.options[optMsgType][0] != wantType {
+			continue
+		}
+		return pkt, nil
+	}
+	return nil, fmt.Errorf("timed out waiting for a DHCP reply")
+}
+
+// buildDhcpPayload builds a raw BOOTP/DHCP request, UDP/IP encapsulated,
+// broadcast on the wire since the client has no address yet.
+func buildDhcpPayload(msgType byte, xid uint32, mac net.HardwareAddr, extra map[byte][]byte) []byte {
+	bootp := new(bytes.Buffer)
+	bootp.WriteByte(1) // op: BOOTREQUEST
+	bootp.WriteByte(1) // htype: ethernet
+	bootp.WriteByte(6) // hlen
+	bootp.WriteByte(0) // hops
+	binary.Write(bootp, binary.BigEndian, xid)
+	bootp.Write(make([]byte, 4))       // secs, flags
+	bootp.Write(make([]byte, 4*4))     // ciaddr, yiaddr, siaddr, giaddr
+	chaddr := make([]byte, 16)
+	copy(chaddr, mac)
+	bootp.Write(chaddr)
+	bootp.Write(make([]byte, 192)) // sname, file
+	bootp.Write([]byte{99, 130, 83, 99}) // DHCP magic cookie
+	bootp.Write([]byte{optMsgType, 1, msgType})
+	for code, value := range extra {
+		bootp.WriteByte(code)
+		bootp.WriteByte(byte(len(value)))
+		bootp.Write(value)
+	}
+	bootp.WriteByte(optEnd)
+
+	udp := udpDatagram(68, 67, bootp.Bytes())
+	ip := ipDatagram(net.IPv4zero, net.IPv4bcast, udp)
+
+	eth := new(bytes.Buffer)
+	eth.Write(broadcastMAC)
+	eth.Write(mac)
+	binary.Write(eth, binary.BigEndian, uint16(ethPIP))
+	eth.Write(ip)
+
+	return eth.Bytes()
+}
+
+func parseDhcpPayload(frame []byte) (*dhcpPacket, error) {
+	if len(frame) < 14+20+8+240 {
+		return nil, fmt.Errorf("short frame")
+	}
+	bootp := frame[14+20+8:]
+	if !bytes.Equal(bootp[236:240], []byte{99, 130, 83, 99}) {
+		return nil, fmt.Errorf("missing DHCP magic cookie")
+	}
+	pkt := &dhcpPacket{
+		xid:     binary.BigEndian.Uint32(bootp[4:8]),
+		yiaddr:  net.IPv4(bootp[16], bootp[17], bootp[18], bootp[19]),
+		options: map[byte][]byte{},
+	}
+	opts := bootp[240:]
+	for i := 0; i < len(opts); {
+		code := opts[i]
+		if code == optEnd {
+			break
+		}
+		if i+1 >= len(opts) {
+			break
+		}
+		length := int(opts[i+1])
+		if i+2+length > len(opts) {
+			break
+		}
+		pkt.options[code] = opts[i+2 : i+2+length]
+		i += 2 + length
+	}
+	return pkt, nil
+}
+
+func leaseFromAck(ack *dhcpPacket) *Lease {
+	lease := &Lease{IP: ack.yiaddr.String()}
+	if mask, ok := ack.options[optSubnetMask]; ok && len(mask) == 4 {
+		lease.Mask = net.IPv4(mask[0], mask[1], mask[2], mask[3]).String()
+	}
+	if gw, ok := ack.options[optRouter]; ok && len(gw) >= 4 {
+		lease.Gateway = net.IPv4(gw[0], gw[1], gw[2], gw[3]).String()
+	}
+	if dns, ok := ack.options[optDNS]; ok {
+		for i := 0; i+4 <= len(dns); i += 4 {
+			lease.DNS = append(lease.DNS, net.IPv4(dns[i], dns[i+1], dns[i+2], dns[i+3]).String())
+		}
+	}
+	if lt, ok := ack.options[optLeaseTime]; ok && len(lt) == 4 {
+		lease.LeaseTime = time.Duration(binary.BigEndian.Uint32(lt)) * time.Second
+	}
+	return lease
+}
+
+// startDhcpRenewal spawns a goroutine that re-requests networkState.Lease
+// at its half-life for as long as the container lives, stopping only
+// when StopDhcpRenewal is called. A failed renewal is logged nowhere and
+// simply retried next cycle; it leaves the last-known lease in place
+// rather than tearing the interface down.
+func startDhcpRenewal(networkState *NetworkState, device string) {
+	networkState.dhcpStop = make(chan struct{})
+	go func() {
+		for {
+			wait := networkState.Lease.LeaseTime / 2
+			if wait <= 0 {
+				wait = 5 * time.Minute
+			}
+			select {
+			case <-time.After(wait):
+			case <-networkState.dhcpStop:
+				return
+			}
+			if lease, err := dhcpLease(networkState.NsPID, device); err == nil {
+				networkState.Lease = lease
+			}
+		}
+	}()
+}
+
+// StopDhcpRenewal stops the background DHCP renewal goroutine Initialize
+// started for networkState. Callers should invoke it when the container
+// this lease belongs to exits.
+func (networkState *NetworkState) StopDhcpRenewal() {
+	if networkState.dhcpStop != nil {
+		close(networkState.dhcpStop)
+	}
+}
+
+var broadcastMAC = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+func htons(v uint16) uint16 {
+	return (v << 8) | (v >> 8)
+}
@@ -0,0 +1,52 @@
+// +build linux
+
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+)
+
+// udpDatagram builds a UDP datagram with a zeroed checksum; DHCP traffic
+// is tolerant of that and it saves computing a pseudo-header checksum
+// over a payload whose source address is 0.0.0.0 anyway.
+func udpDatagram(srcPort, dstPort uint16, payload []byte) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, srcPort)
+	binary.Write(buf, binary.BigEndian, dstPort)
+	binary.Write(buf, binary.BigEndian, uint16(8+len(payload)))
+	binary.Write(buf, binary.BigEndian, uint16(0)) // checksum
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// ipDatagram builds a minimal IPv4 header around payload with the
+// header checksum filled in.
+func ipDatagram(src, dst net.IP, payload []byte) []byte {
+	header := make([]byte, 20)
+	header[0] = 0x45 // version 4, IHL 5
+	header[1] = 0    // DSCP/ECN
+	binary.BigEndian.PutUint16(header[2:4], uint16(20+len(payload)))
+	binary.BigEndian.PutUint16(header[4:6], 0) // identification
+	binary.BigEndian.PutUint16(header[6:8], 0) // flags/fragment offset
+	header[8] = 64                             // TTL
+	header[9] = 17                             // protocol: UDP
+	binary.BigEndian.PutUint16(header[10:12], 0)
+	copy(header[12:16], src.To4())
+	copy(header[16:20], dst.To4())
+	binary.BigEndian.PutUint16(header[10:12], ipChecksum(header))
+
+	return append(header, payload...)
+}
+
+func ipChecksum(header []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(header); i += 2 {
+		sum += uint32(header[i])<<8 | uint32(header[i+1])
+	}
+	for sum>>16 != 0 {
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,92 @@
+// +build linux
+
+package network
+
+import (
+	"fmt"
+
+	"github.com/docker/libcontainer/netlink"
+	"github.com/docker/libcontainer/utils"
+)
+
+// Ipvlan is the same idea as Macvlan but shares a single MAC address
+// with the parent device, which some uplinks require (e.g. switches
+// that cap the number of MACs learned per port).
+type Ipvlan struct {
+}
+
+func (i *Ipvlan) Create(n *Network, nspid int, networkState *NetworkState) error {
+	if n.Parent == "" {
+		return fmt.Errorf("parent is not specified")
+	}
+	parent, err := netlink.NetworkLinkByName(n.Parent)
+	if err != nil {
+		return fmt.Errorf("get parent %s %s", n.Parent, err)
+	}
+	name, err := utils.GenerateRandomName("ipvlan", 7)
+	if err != nil {
+		return err
+	}
+	link := &netlink.Ipvlan{
+		NetworkLink: netlink.NetworkLink{
+			Name:        name,
+			ParentIndex: parent.Index,
+		},
+		Mode: ipvlanMode(n.Mode),
+	}
+	if err := netlink.NetworkLinkAdd(link); err != nil {
+		return fmt.Errorf("create ipvlan %s", err)
+	}
+	if err := SetMtu(name, n.Mtu); err != nil {
+		return err
+	}
+	if err := SetInterfaceInNamespacePid(name, nspid); err != nil {
+		return err
+	}
+	networkState.VethChild = name
+	networkState.NsPID = nspid
+	return nil
+}
+
+func (i *Ipvlan) Initialize(config *Network, networkState *NetworkState) error {
+	child := networkState.VethChild
+	if child == "" {
+		return fmt.Errorf("interface is not specified")
+	}
+	device := config.Name
+	if device == "" {
+		device = defaultDevice
+	}
+	if err := InterfaceDown(child); err != nil {
+		return fmt.Errorf("interface down %s %s", child, err)
+	}
+	if err := ChangeInterfaceName(child, device); err != nil {
+		return fmt.Errorf("change %s to %s %s", child, device, err)
+	}
+	networkState.Device = device
+	return configureInterface(device, config)
+}
+
+// Attach is not supported; an ipvlan interface is parented to a host
+// uplink at creation time and cannot be rebound to a different one.
+func (i *Ipvlan) Attach(config *Network, networkState *NetworkState) error {
+	return fmt.Errorf("ipvlan does not support attach")
+}
+
+// Detach is not supported for the same reason as Attach.
+func (i *Ipvlan) Detach(config *Network, networkState *NetworkState) error {
+	return fmt.Errorf("ipvlan does not support detach")
+}
+
+func (i *Ipvlan) Stats(networkState *NetworkState) (*NetworkInterface, error) {
+	return GetStats(networkState)
+}
+
+func ipvlanMode(mode string) uint16 {
+	switch mode {
+	case "l3":
+		return netlink.IPVLAN_MODE_L3
+	default:
+		return netlink.IPVLAN_MODE_L2
+	}
+}
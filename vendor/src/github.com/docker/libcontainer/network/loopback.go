@@ -0,0 +1,36 @@
+// +build linux
+
+package network
+
+// Loopback brings up the "lo" device that already exists in every new
+// network namespace. It exists so that a container's network slice can
+// request it explicitly instead of silently relying on whatever process
+// enters the namespace next to bring it up.
+type Loopback struct {
+}
+
+func (l *Loopback) Create(n *Network, nspid int, networkState *NetworkState) error {
+	networkState.NsPID = nspid
+	networkState.Device = "lo"
+	return nil
+}
+
+func (l *Loopback) Initialize(config *Network, networkState *NetworkState) error {
+	return InterfaceUp("lo")
+}
+
+// Attach is a no-op; the loopback device has no host side to rebind to a
+// different bridge.
+func (l *Loopback) Attach(config *Network, networkState *NetworkState) error {
+	return nil
+}
+
+// Detach is a no-op; the loopback device has no host side to remove from
+// a bridge.
+func (l *Loopback) Detach(config *Network, networkState *NetworkState) error {
+	return nil
+}
+
+func (l *Loopback) Stats(networkState *NetworkState) (*NetworkInterface, error) {
+	return GetStats(networkState)
+}
@@ -0,0 +1,97 @@
+// +build linux
+
+package network
+
+import (
+	"fmt"
+
+	"github.com/docker/libcontainer/netlink"
+	"github.com/docker/libcontainer/utils"
+)
+
+// Macvlan is a network strategy that gives a container a first-class L2
+// presence on the host's uplink device, bypassing the bridge + NAT path
+// Veth requires. It creates a macvlan sub-interface of Network.Parent and
+// moves it into the container's namespace.
+type Macvlan struct {
+}
+
+func (m *Macvlan) Create(n *Network, nspid int, networkState *NetworkState) error {
+	if n.Parent == "" {
+		return fmt.Errorf("parent is not specified")
+	}
+	parent, err := netlink.NetworkLinkByName(n.Parent)
+	if err != nil {
+		return fmt.Errorf("get parent %s %s", n.Parent, err)
+	}
+	name, err := utils.GenerateRandomName("mcvlan", 7)
+	if err != nil {
+		return err
+	}
+	link := &netlink.Macvlan{
+		NetworkLink: netlink.NetworkLink{
+			Name:        name,
+			ParentIndex: parent.Index,
+		},
+		Mode: macvlanMode(n.Mode),
+	}
+	if err := netlink.NetworkLinkAdd(link); err != nil {
+		return fmt.Errorf("create macvlan %s", err)
+	}
+	if err := SetMtu(name, n.Mtu); err != nil {
+		return err
+	}
+	if err := SetInterfaceInNamespacePid(name, nspid); err != nil {
+		return err
+	}
+	networkState.VethChild = name
+	networkState.NsPID = nspid
+	return nil
+}
+
+func (m *Macvlan) Initialize(config *Network, networkState *NetworkState) error {
+	child := networkState.VethChild
+	if child == "" {
+		return fmt.Errorf("interface is not specified")
+	}
+	device := config.Name
+	if device == "" {
+		device = defaultDevice
+	}
+	if err := InterfaceDown(child); err != nil {
+		return fmt.Errorf("interface down %s %s", child, err)
+	}
+	if err := ChangeInterfaceName(child, device); err != nil {
+		return fmt.Errorf("change %s to %s %s", child, device, err)
+	}
+	networkState.Device = device
+	return configureInterface(device, config)
+}
+
+// Attach is not supported; a macvlan interface is parented to a host
+// uplink at creation time and cannot be rebound to a different one.
+func (m *Macvlan) Attach(config *Network, networkState *NetworkState) error {
+	return fmt.Errorf("macvlan does not support attach")
+}
+
+// Detach is not supported for the same reason as Attach.
+func (m *Macvlan) Detach(config *Network, networkState *NetworkState) error {
+	return fmt.Errorf("macvlan does not support detach")
+}
+
+func (m *Macvlan) Stats(networkState *NetworkState) (*NetworkInterface, error) {
+	return GetStats(networkState)
+}
+
+func macvlanMode(mode string) uint32 {
+	switch mode {
+	case "private":
+		return netlink.MACVLAN_MODE_PRIVATE
+	case "vepa":
+		return netlink.MACVLAN_MODE_VEPA
+	case "passthru":
+		return netlink.MACVLAN_MODE_PASSTHRU
+	default:
+		return netlink.MACVLAN_MODE_BRIDGE
+	}
+}
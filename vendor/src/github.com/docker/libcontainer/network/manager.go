@@ -0,0 +1,122 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+)
+
+// interfaceState pairs a Network's configuration with the NetworkState
+// Create produced for it, so a later Attach/Detach call has everything
+// the strategy needs without the caller threading both through again.
+type interfaceState struct {
+	network *Network
+	state   *NetworkState
+}
+
+// Manager tracks the live network interfaces of running containers, keyed
+// by container PID, so that Attach and Detach can be issued against a
+// container after it has already been created. This is what makes
+// operations like live-migrating a container to a different bridge or
+// pausing its networking for troubleshooting possible without tearing the
+// container down.
+type Manager struct {
+	mu         sync.Mutex
+	containers map[int][]*interfaceState
+}
+
+// NewManager returns an empty Manager ready to track containers.
+func NewManager() *Manager {
+	return &Manager{
+		containers: make(map[int][]*interfaceState),
+	}
+}
+
+// Add registers the interfaces of a container that has just been created
+// so that Attach/Detach can later be issued against it by PID and name.
+func (m *Manager) Add(pid int, networks []*Network, networkStates []*NetworkState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ifaces := make([]*interfaceState, len(networks))
+	for i, n := range networks {
+		ifaces[i] = &interfaceState{network: n, state: networkStates[i]}
+	}
+	m.containers[pid] = ifaces
+}
+
+// Remove stops tracking a container, e.g. once it has exited. It also
+// stops any DHCP renewal goroutine running against the container's
+// namespace, since nothing else will once its PID is forgotten here.
+func (m *Manager) Remove(pid int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, iface := range m.containers[pid] {
+		iface.state.StopDhcpRenewal()
+	}
+	delete(m.containers, pid)
+}
+
+// Detach removes the named interface of the container at pid from its
+// current bridge without destroying the container's namespace end.
+func (m *Manager) Detach(pid int, name string) error {
+	iface, err := m.find(pid, name)
+	if err != nil {
+		return err
+	}
+	strategy, err := getStrategy(iface.network.Type)
+	if err != nil {
+		return err
+	}
+	return strategy.Detach(iface.network, iface.state)
+}
+
+// Attach re-binds the named interface of the container at pid to bridge,
+// updating the tracked Network so later calls see the new bridge.
+func (m *Manager) Attach(pid int, name string, bridge string) error {
+	iface, err := m.attach(pid, name, bridge)
+	if err != nil {
+		return err
+	}
+	strategy, err := getStrategy(iface.network.Type)
+	if err != nil {
+		return err
+	}
+	return strategy.Attach(iface.network, iface.state)
+}
+
+// attach looks up the named interface of pid and sets its tracked Bridge
+// to bridge, all under m.mu, so two concurrent Attach calls for the same
+// interface can't race on that write.
+func (m *Manager) attach(pid int, name string, bridge string) (*interfaceState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	iface, err := m.findLocked(pid, name)
+	if err != nil {
+		return nil, err
+	}
+	iface.network.Bridge = bridge
+	return iface, nil
+}
+
+func (m *Manager) find(pid int, name string) (*interfaceState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.findLocked(pid, name)
+}
+
+// findLocked looks up the named interface of pid. Callers must hold m.mu.
+func (m *Manager) findLocked(pid int, name string) (*interfaceState, error) {
+	ifaces, exists := m.containers[pid]
+	if !exists {
+		return nil, fmt.Errorf("no tracked networks for pid %d", pid)
+	}
+	for _, iface := range ifaces {
+		if iface.network.Name == name {
+			return iface, nil
+		}
+	}
+	return nil, fmt.Errorf("no %q interface tracked for pid %d", name, pid)
+}
@@ -0,0 +1,87 @@
+package network
+
+// Network defines configuration for a single interface inside a
+// container's networking stack.
+//
+// A container config carries a slice of *Network so that a single
+// container can be given more than one interface (e.g. one bridge for
+// east-west traffic and another for management). The container is left
+// with the host's networking stack if the slice is empty.
+type Network struct {
+	// Type sets the strategy used to create the interface, e.g. "veth"
+	// or "loopback", and is used to look the strategy up in the
+	// strategies map in strategy.go
+	Type string `json:"type"`
+
+	// Name is the name given to the interface once it is moved inside
+	// the container's namespace, e.g. eth0, eth1. Each entry in a
+	// container's network slice should use a distinct Name.
+	Name string `json:"name"`
+
+	Bridge     string `json:"bridge"`
+	VethPrefix string `json:"veth_prefix"`
+
+	// Parent is the host uplink device a macvlan/ipvlan interface is
+	// created against, e.g. eth0.
+	Parent string `json:"parent"`
+
+	// Mode selects the sub-type of a macvlan ("bridge", "private",
+	// "vepa", "passthru") or ipvlan ("l2", "l3") interface.
+	Mode string `json:"mode"`
+
+	Address     string `json:"address"`
+	Gateway     string `json:"gateway"`
+	IPv6Address string `json:"ipv6_address"`
+	IPv6Gateway string `json:"ipv6_gateway"`
+
+	Mtu        int  `json:"mtu"`
+	TxQueueLen int  `json:"txqueuelen"`
+	Dhcp       bool `json:"dhcp"`
+
+	MacAddress string `json:"mac_address"`
+
+	// Routes are additional routes added to the container's interface
+	// beyond the default gateway, e.g. for split-tunnel setups where
+	// only a subset of destinations should go out this interface.
+	Routes []Route `json:"routes"`
+
+	// Sysctls are net.* sysctl keys applied inside the container's
+	// namespace after its interface is renamed and before it is brought
+	// up, e.g. net.ipv4.conf.eth0.rp_filter. Only the veth strategy
+	// applies them; loopback, macvlan and ipvlan ignore this field.
+	Sysctls map[string]string `json:"sysctls"`
+}
+
+// Route is a single routing table entry added on top of the default
+// gateway.
+type Route struct {
+	Dst    string `json:"dst"`
+	GW     string `json:"gw"`
+	Src    string `json:"src"`
+	Metric int    `json:"metric"`
+}
+
+// NetworkState keeps track of the runtime state of a single container
+// interface so that Initialize and any later teardown can find the
+// resources that Create allocated for it. A container with more than one
+// Network entry has one NetworkState per entry, in the same order.
+type NetworkState struct {
+	VethHost  string `json:"veth_host"`
+	VethChild string `json:"veth_child"`
+	NsPID     int    `json:"ns_pid"`
+
+	// Device is the name the interface was given inside the container's
+	// namespace, e.g. eth0, eth1. Initialize sets it, except for
+	// loopback, whose Create sets it to "lo". Stats uses it to find
+	// the interface to read counters for.
+	Device string `json:"device"`
+
+	// Lease is the DHCP lease Initialize obtained for Device, if
+	// config.Dhcp was set. It lives here instead of under /var/run or
+	// /var/lib on the host so it cleans up with the container.
+	Lease *Lease `json:"lease,omitempty"`
+
+	// dhcpStop, when non-nil, signals the background renewal goroutine
+	// Initialize started for Lease to exit. See StopDhcpRenewal.
+	dhcpStop chan struct{}
+}
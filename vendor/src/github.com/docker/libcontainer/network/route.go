@@ -0,0 +1,31 @@
+// +build linux
+
+package network
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/docker/libcontainer/netlink"
+)
+
+// AddRoute adds a unicast route for dst via gw on iface, optionally
+// pinned to src and metric. An equivalent route already existing (e.g.
+// on a retried Initialize) is not an error; the first route added wins.
+func AddRoute(dst, gw, src string, metric int, iface string) error {
+	route := &netlink.Route{
+		Dst:    dst,
+		Gw:     gw,
+		Src:    src,
+		Metric: metric,
+		Iface:  iface,
+		Type:   netlink.RTN_UNICAST,
+	}
+	if err := netlink.RouteAdd(route); err != nil {
+		if os.IsExist(err) || err == syscall.EEXIST {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
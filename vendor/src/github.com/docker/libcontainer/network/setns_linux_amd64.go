@@ -0,0 +1,8 @@
+// +build linux,amd64
+
+package network
+
+// sysSetns is syscall.SYS_SETNS on amd64. The generated syscall table for
+// this arch predates setns and never picked up the number, so it is hard
+// coded here.
+const sysSetns = 308
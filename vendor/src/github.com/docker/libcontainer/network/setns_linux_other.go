@@ -0,0 +1,10 @@
+// +build linux,!amd64
+
+package network
+
+import "syscall"
+
+// sysSetns is syscall.SYS_SETNS on every Linux arch except amd64. The
+// linux/386 syscall table has no SYS_SETNS, so this file does not
+// compile there.
+const sysSetns = syscall.SYS_SETNS
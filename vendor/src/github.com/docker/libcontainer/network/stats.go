@@ -0,0 +1,117 @@
+// +build linux
+
+package network
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// NetworkInterface holds the bandwidth counters for a single container
+// interface, gathered from the sysfs statistics/ directory of the
+// in-namespace device.
+type NetworkInterface struct {
+	Name string `json:"name"`
+
+	RxBytes   uint64 `json:"rx_bytes"`
+	RxPackets uint64 `json:"rx_packets"`
+	RxErrors  uint64 `json:"rx_errors"`
+	RxDropped uint64 `json:"rx_dropped"`
+
+	TxBytes   uint64 `json:"tx_bytes"`
+	TxPackets uint64 `json:"tx_packets"`
+	TxErrors  uint64 `json:"tx_errors"`
+	TxDropped uint64 `json:"tx_dropped"`
+}
+
+// statFields lists the sysfs statistics/ files GetStats reads, and where
+// each value is stored on the returned NetworkInterface.
+var statFields = []struct {
+	file string
+	dest func(*NetworkInterface) *uint64
+}{
+	{"rx_bytes", func(n *NetworkInterface) *uint64 { return &n.RxBytes }},
+	{"rx_packets", func(n *NetworkInterface) *uint64 { return &n.RxPackets }},
+	{"rx_errors", func(n *NetworkInterface) *uint64 { return &n.RxErrors }},
+	{"rx_dropped", func(n *NetworkInterface) *uint64 { return &n.RxDropped }},
+	{"tx_bytes", func(n *NetworkInterface) *uint64 { return &n.TxBytes }},
+	{"tx_packets", func(n *NetworkInterface) *uint64 { return &n.TxPackets }},
+	{"tx_errors", func(n *NetworkInterface) *uint64 { return &n.TxErrors }},
+	{"tx_dropped", func(n *NetworkInterface) *uint64 { return &n.TxDropped }},
+}
+
+// GetStats enters the network namespace of networkState.NsPID and reads
+// the bandwidth counters for networkState.Device out of
+// /sys/class/net/<device>/statistics/. A container mid-teardown, whether
+// its process (and so /proc/<pid>/ns/net) has already exited or just its
+// device has already disappeared from within a namespace that's still
+// open, is not treated as an error; GetStats returns a NetworkInterface
+// with only Name set and every counter zero, so callers polling stats
+// don't have to race container exit.
+func GetStats(networkState *NetworkState) (*NetworkInterface, error) {
+	iface := &NetworkInterface{Name: networkState.Device}
+
+	nsPath := fmt.Sprintf("/proc/%d/ns/net", networkState.NsPID)
+	nsFile, err := os.Open(nsPath)
+	if os.IsNotExist(err) {
+		return iface, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open %s %s", nsPath, err)
+	}
+	defer nsFile.Close()
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	origNs, err := os.Open("/proc/self/ns/net")
+	if err != nil {
+		return nil, fmt.Errorf("open /proc/self/ns/net %s", err)
+	}
+	defer origNs.Close()
+
+	if err := setNs(nsFile.Fd()); err != nil {
+		return nil, fmt.Errorf("enter netns of pid %d %s", networkState.NsPID, err)
+	}
+	defer setNs(origNs.Fd())
+
+	statDir := filepath.Join("/sys/class/net", networkState.Device, "statistics")
+	if _, err := os.Stat(statDir); os.IsNotExist(err) {
+		return iface, nil
+	}
+
+	for _, f := range statFields {
+		v, err := readStatFile(filepath.Join(statDir, f.file))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		*f.dest(iface) = v
+	}
+
+	return iface, nil
+}
+
+func readStatFile(path string) (uint64, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+func setNs(fd uintptr) error {
+	_, _, errno := syscall.RawSyscall(sysSetns, fd, uintptr(syscall.CLONE_NEWNET), 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package network
+
+import "fmt"
+
+// networkStrategy represents a specific network configuration for
+// a container's networking stack
+type networkStrategy interface {
+	Create(*Network, int, *NetworkState) error
+	Initialize(*Network, *NetworkState) error
+	Attach(*Network, *NetworkState) error
+	Detach(*Network, *NetworkState) error
+	Stats(*NetworkState) (*NetworkInterface, error)
+}
+
+// strategies is a map of all the network strategies available in the
+// system, keyed off of the Network.Type each one is registered under
+var strategies = map[string]networkStrategy{
+	"veth":     &Veth{},
+	"loopback": &Loopback{},
+	"macvlan":  &Macvlan{},
+	"ipvlan":   &Ipvlan{},
+}
+
+// getStrategy returns the registered networkStrategy for tpe or an error
+// if no strategy is registered under that name
+func getStrategy(tpe string) (networkStrategy, error) {
+	s, exists := strategies[tpe]
+	if !exists {
+		return nil, fmt.Errorf("unknown network type %q", tpe)
+	}
+	return s, nil
+}
+
+// Create sets up a host-side interface for every entry in networks,
+// dispatching each one to its registered strategy, and returns the
+// per-interface state in the same order so it can be persisted and
+// later handed to Initialize.
+func Create(networks []*Network, nspid int) ([]*NetworkState, error) {
+	networkStates := make([]*NetworkState, len(networks))
+	for i, n := range networks {
+		strategy, err := getStrategy(n.Type)
+		if err != nil {
+			return nil, err
+		}
+		state := &NetworkState{}
+		if err := strategy.Create(n, nspid, state); err != nil {
+			return nil, err
+		}
+		networkStates[i] = state
+	}
+	return networkStates, nil
+}
+
+// Initialize finishes configuring every interface in networks from
+// inside the container's namespace, pairing each entry with the
+// NetworkState Create produced for it.
+func Initialize(networks []*Network, networkStates []*NetworkState) error {
+	for i, n := range networks {
+		strategy, err := getStrategy(n.Type)
+		if err != nil {
+			return err
+		}
+		if err := strategy.Initialize(n, networkStates[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+// +build linux
+
+package network
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// applySysctls writes each key/value in sysctls to its matching
+// /proc/sys path. Only keys under the net. subsystem are allowed, so a
+// container config cannot use this to retune unrelated kernel or VM
+// knobs; it must be called from inside the target namespace, since
+// /proc/sys/net is itself namespaced.
+func applySysctls(sysctls map[string]string) error {
+	for key, value := range sysctls {
+		if !strings.HasPrefix(key, "net.") {
+			return fmt.Errorf("sysctl %q is outside the net. namespace", key)
+		}
+		path := filepath.Clean(filepath.Join("/proc/sys", strings.Replace(key, ".", "/", -1)))
+		if err := ioutil.WriteFile(path, []byte(value), 0644); err != nil {
+			return fmt.Errorf("set sysctl %s to %s %s", key, value, err)
+		}
+	}
+	return nil
+}
@@ -7,8 +7,6 @@ import (
 
 	"github.com/docker/libcontainer/netlink"
 	"github.com/docker/libcontainer/utils"
-	"strconv"
-	"os/exec"
 )
 
 // Veth is a network strategy that uses a bridge and creates
@@ -60,56 +58,87 @@ func (v *Veth) Initialize(config *Network, networkState *NetworkState) error {
 	if vethChild == "" {
 		return fmt.Errorf("vethChild is not specified")
 	}
+	device := config.Name
+	if device == "" {
+		device = defaultDevice
+	}
 	if err := InterfaceDown(vethChild); err != nil {
 		return fmt.Errorf("interface down %s %s", vethChild, err)
 	}
-	if err := ChangeInterfaceName(vethChild, defaultDevice); err != nil {
-		return fmt.Errorf("change %s to %s %s", vethChild, defaultDevice, err)
+	if err := ChangeInterfaceName(vethChild, device); err != nil {
+		return fmt.Errorf("change %s to %s %s", vethChild, device, err)
 	}
-	if config.MacAddress != "" {
-		if err := SetInterfaceMac(defaultDevice, config.MacAddress); err != nil {
-			return fmt.Errorf("set %s mac %s", defaultDevice, err)
-		}
+	networkState.Device = device
+	if err := applySysctls(config.Sysctls); err != nil {
+		return err
 	}
-	if err := SetInterfaceIp(defaultDevice, config.Address); err != nil {
-		return fmt.Errorf("set %s ip %s", defaultDevice, err)
+	if err := configureInterface(device, config); err != nil {
+		return err
 	}
-	if config.IPv6Address != "" {
-		if err := SetInterfaceIp(defaultDevice, config.IPv6Address); err != nil {
-			return fmt.Errorf("set %s ipv6 %s", defaultDevice, err)
+
+	if config.Dhcp {
+		lease, err := dhcpLease(networkState.NsPID, device)
+		if err != nil {
+			return fmt.Errorf("dhcp lease for %s %s", device, err)
 		}
+		networkState.Lease = lease
+		if err := SetInterfaceIp(device, lease.IP+"/"+lease.Mask); err != nil {
+			return fmt.Errorf("set %s ip from dhcp lease %s", device, err)
+		}
+		if lease.Gateway != "" {
+			if err := SetDefaultGateway(lease.Gateway, device); err != nil {
+				return fmt.Errorf("set gateway to %s on device %s failed with %s", lease.Gateway, device, err)
+			}
+		}
+		startDhcpRenewal(networkState, device)
 	}
 
-	if err := SetMtu(defaultDevice, config.Mtu); err != nil {
-		return fmt.Errorf("set %s mtu to %d %s", defaultDevice, config.Mtu, err)
+	return nil
+}
+
+// Detach removes the host side of the veth pair from its bridge master,
+// leaving the container's end of the pair untouched so the interface can
+// later be re-attached with Attach instead of recreated.
+func (v *Veth) Detach(config *Network, networkState *NetworkState) error {
+	var vethHost = networkState.VethHost
+	if vethHost == "" {
+		return fmt.Errorf("vethHost is not specified")
 	}
-	if err := InterfaceUp(defaultDevice); err != nil {
-		return fmt.Errorf("%s up %s", defaultDevice, err)
+	if err := ClearInterfaceMaster(vethHost); err != nil {
+		return fmt.Errorf("clear master of %s %s", vethHost, err)
 	}
-	if config.Gateway != "" {
-		if err := SetDefaultGateway(config.Gateway, defaultDevice); err != nil {
-			return fmt.Errorf("set gateway to %s on device %s failed with %s", config.Gateway, defaultDevice, err)
-		}
+	return nil
+}
+
+// Attach re-binds the host side of an existing veth pair to config.Bridge,
+// which may be a different bridge than the one it was created or last
+// attached to, and re-applies the MTU before bringing it back up.
+func (v *Veth) Attach(config *Network, networkState *NetworkState) error {
+	var vethHost = networkState.VethHost
+	if vethHost == "" {
+		return fmt.Errorf("vethHost is not specified")
 	}
-	if config.IPv6Gateway != "" {
-		if err := SetDefaultGateway(config.IPv6Gateway, defaultDevice); err != nil {
-			return fmt.Errorf("set gateway for ipv6 to %s on device %s failed with %s", config.IPv6Gateway, defaultDevice, err)
-		}
+	if config.Bridge == "" {
+		return fmt.Errorf("bridge is not specified")
 	}
-
-	if config.Dhcp {
-		pidstr := strconv.Itoa(networkState.NsPID)
-		cmd := exec.Command("ip", "netns", "exec", pidstr, "dhclient", "-4", "-pf",
-			"/var/run/dhclient-" + pidstr + ".pid", "-lf", "/var/lib/dhclient/" + pidstr + ".lease", defaultDevice)
-		err := cmd.Run()
-		if err != nil {
-			fmt.Errorf("Was not able to start dhclient")
-		}
+	if err := SetInterfaceMaster(vethHost, config.Bridge); err != nil {
+		return fmt.Errorf("set master of %s to %s %s", vethHost, config.Bridge, err)
+	}
+	if err := SetMtu(vethHost, config.Mtu); err != nil {
+		return fmt.Errorf("set %s mtu to %d %s", vethHost, config.Mtu, err)
+	}
+	if err := InterfaceUp(vethHost); err != nil {
+		return fmt.Errorf("%s up %s", vethHost, err)
 	}
-
 	return nil
 }
 
+// Stats returns the bandwidth counters for the container's end of the
+// veth pair.
+func (v *Veth) Stats(networkState *NetworkState) (*NetworkInterface, error) {
+	return GetStats(networkState)
+}
+
 // createVethPair will automatically generage two random names for
 // the veth pair and ensure that they have been created
 func createVethPair(prefix string, txQueueLen int) (name1 string, name2 string, err error) {